@@ -0,0 +1,148 @@
+// whereabouts-shim is the CNI-facing binary installed in place of the full
+// whereabouts plugin when daemon mode is enabled. It does no IPAM work
+// itself: it forwards Allocate/Deallocate calls to the node-local
+// whereaboutsd over a unix socket and translates the response back into a
+// CNI result, so the CNI runtime never pays client/datastore startup cost.
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/containernetworking/cni/pkg/skel"
+	cnitypes "github.com/containernetworking/cni/pkg/types"
+	current "github.com/containernetworking/cni/pkg/types/100"
+	"github.com/containernetworking/cni/pkg/version"
+	googlegrpc "google.golang.org/grpc"
+
+	"github.com/dougbtv/whereabouts/pkg/config"
+	"github.com/dougbtv/whereabouts/pkg/rpc"
+	"github.com/dougbtv/whereabouts/pkg/types"
+)
+
+func dial(ipamConf *types.IPAMConfig) (rpc.WhereaboutsClient, *googlegrpc.ClientConn, error) {
+	socketPath := types.DefaultDaemonSocketPath
+	timeout := time.Duration(ipamConf.RequestTimeout) * time.Second
+
+	conn, err := googlegrpc.Dial(
+		"unix:"+socketPath,
+		googlegrpc.WithInsecure(),
+		googlegrpc.WithBlock(),
+		googlegrpc.WithTimeout(timeout),
+		googlegrpc.WithDialer(func(addr string, timeout time.Duration) (net.Conn, error) {
+			return net.DialTimeout("unix", socketPath, timeout)
+		}),
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("whereabouts-shim: error dialing whereaboutsd at %s: %v", socketPath, err)
+	}
+
+	return rpc.NewWhereaboutsClient(conn), conn, nil
+}
+
+func cmdAdd(args *skel.CmdArgs) error {
+	ipamConf, cniVersion, err := config.LoadIPAMConfig(args.StdinData, args.Args)
+	if err != nil {
+		return err
+	}
+
+	client, conn, err := dial(ipamConf)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(ipamConf.RequestTimeout)*time.Second)
+	defer cancel()
+
+	resp, err := client.Allocate(ctx, &rpc.AllocateRequest{
+		ContainerId: args.ContainerID,
+		PodRef:      string(args.Args),
+		IfName:      args.IfName,
+		PoolName:    ipamConf.PoolName,
+	})
+	if err != nil {
+		return err
+	}
+
+	var gateway net.IP
+	if resp.Gateway != "" {
+		gateway = net.ParseIP(resp.Gateway)
+	}
+
+	result := &current.Result{CNIVersion: cniVersion}
+	for _, ip := range resp.Ips {
+		ipnet, err := cnitypes.ParseCIDR(ip)
+		if err != nil {
+			return fmt.Errorf("whereabouts-shim: invalid IP %q from daemon: %v", ip, err)
+		}
+		result.IPs = append(result.IPs, &current.IPConfig{Address: *ipnet, Gateway: gateway})
+	}
+	for _, route := range resp.Routes {
+		_, dst, err := net.ParseCIDR(route)
+		if err != nil {
+			return fmt.Errorf("whereabouts-shim: invalid route %q from daemon: %v", route, err)
+		}
+		result.Routes = append(result.Routes, &cnitypes.Route{Dst: *dst, GW: gateway})
+	}
+
+	return cnitypes.PrintResult(result, cniVersion)
+}
+
+func cmdDel(args *skel.CmdArgs) error {
+	ipamConf, _, err := config.LoadIPAMConfig(args.StdinData, args.Args)
+	if err != nil {
+		return err
+	}
+
+	client, conn, err := dial(ipamConf)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(ipamConf.RequestTimeout)*time.Second)
+	defer cancel()
+
+	_, err = client.Deallocate(ctx, &rpc.DeallocateRequest{
+		ContainerId: args.ContainerID,
+		IfName:      args.IfName,
+		PoolName:    ipamConf.PoolName,
+	})
+	return err
+}
+
+func cmdCheck(args *skel.CmdArgs) error {
+	ipamConf, _, err := config.LoadIPAMConfig(args.StdinData, args.Args)
+	if err != nil {
+		return err
+	}
+
+	client, conn, err := dial(ipamConf)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(ipamConf.RequestTimeout)*time.Second)
+	defer cancel()
+
+	resp, err := client.IsAllocated(ctx, &rpc.IsAllocatedRequest{
+		ContainerId: args.ContainerID,
+		IfName:      args.IfName,
+		PoolName:    ipamConf.PoolName,
+	})
+	if err != nil {
+		return err
+	}
+	if !resp.Allocated {
+		return fmt.Errorf("whereabouts-shim: no reservation found for container %s", args.ContainerID)
+	}
+	return nil
+}
+
+func main() {
+	skel.PluginMain(cmdAdd, cmdCheck, cmdDel, version.All, "whereabouts-shim")
+}
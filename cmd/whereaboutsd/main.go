@@ -0,0 +1,66 @@
+// whereaboutsd is a node-local daemon that holds the whereabouts datastore
+// connection open across pod add/del events and serves allocation requests
+// over a unix socket, so that whereabouts-shim doesn't pay per-invocation
+// client startup cost. See pkg/daemon and pkg/rpc/whereabouts.proto.
+package main
+
+import (
+	"context"
+	"flag"
+	"io/ioutil"
+	"net"
+	"os"
+
+	googlegrpc "google.golang.org/grpc"
+
+	"github.com/dougbtv/whereabouts/pkg/config"
+	"github.com/dougbtv/whereabouts/pkg/daemon"
+	"github.com/dougbtv/whereabouts/pkg/logging"
+	"github.com/dougbtv/whereabouts/pkg/rpc"
+	"github.com/dougbtv/whereabouts/pkg/types"
+)
+
+func main() {
+	configPath := flag.String("config", types.WhereaboutsConfigPath, "path to the whereabouts flat-file config")
+	socketPath := flag.String("socket", types.DefaultDaemonSocketPath, "unix socket to listen on")
+	flag.Parse()
+
+	confBytes, err := ioutil.ReadFile(*configPath)
+	if err != nil {
+		logging.Errorf("whereaboutsd: error reading %s: %v", *configPath, err)
+		os.Exit(1)
+	}
+
+	ipamConf, err := config.LoadDaemonConfig(confBytes)
+	if err != nil {
+		logging.Errorf("whereaboutsd: error loading config: %v", err)
+		os.Exit(1)
+	}
+
+	if err := os.Remove(*socketPath); err != nil && !os.IsNotExist(err) {
+		logging.Errorf("whereaboutsd: error removing stale socket %s: %v", *socketPath, err)
+		os.Exit(1)
+	}
+
+	listener, err := net.Listen("unix", *socketPath)
+	if err != nil {
+		logging.Errorf("whereaboutsd: error listening on %s: %v", *socketPath, err)
+		os.Exit(1)
+	}
+
+	server, err := daemon.NewServer(context.Background(), *ipamConf)
+	if err != nil {
+		logging.Errorf("whereaboutsd: error starting server: %v", err)
+		os.Exit(1)
+	}
+	defer server.Close(context.Background())
+
+	grpcServer := googlegrpc.NewServer()
+	rpc.RegisterWhereaboutsServer(grpcServer, server)
+
+	logging.Verbosef("whereaboutsd: listening on %s", *socketPath)
+	if err := grpcServer.Serve(listener); err != nil {
+		logging.Errorf("whereaboutsd: serve error: %v", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,150 @@
+// Package allocate contains the IP selection logic used to pick the next
+// free address out of a reservation list for a given range.
+package allocate
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/dougbtv/whereabouts/pkg/types"
+)
+
+// AssignIP assigns an IP using a range and a set of reservations for a single range/pool
+func AssignIP(ipamConf types.RangeConfiguration, reservelist []types.IPReservation, containerID string, ifName string, podRef string) (net.IPNet, []types.IPReservation, error) {
+	// Setup the basics here.
+	_, ipnet, err := net.ParseCIDR(ipamConf.Range)
+	if err != nil {
+		return net.IPNet{}, reservelist, err
+	}
+
+	newIP, updatedreservelist, err := IterateForAssignment(*ipnet, ipamConf.RangeStart, ipamConf.RangeEnd, reservelist, ipamConf.OmitRanges, containerID, ifName, podRef)
+	if err != nil {
+		return net.IPNet{}, nil, err
+	}
+
+	return net.IPNet{IP: newIP, Mask: ipnet.Mask}, updatedreservelist, nil
+}
+
+// DeallocateIP removes the reservation held by the (containerID, ifName) tuple from
+// the given range's reservation list. Records written before ifName existed have it
+// empty; those are matched on containerID alone as a migration path.
+func DeallocateIP(ipRange string, reservelist []types.IPReservation, containerID string, ifName string) ([]types.IPReservation, error) {
+	updatedreservelist, _, err := IterateForDeallocation(reservelist, containerID, ifName, getMatchingIPReservationIndex)
+	if err != nil {
+		return nil, err
+	}
+	return updatedreservelist, nil
+}
+
+func getMatchingIPReservationIndex(reservation types.IPReservation, containerID string, ifName string) bool {
+	if reservation.ContainerID != containerID {
+		return false
+	}
+	// Pre-migration records have no IfName recorded; match on containerID alone.
+	if reservation.IfName == "" {
+		return true
+	}
+	return reservation.IfName == ifName
+}
+
+// IterateForDeallocation iterates overs currently reserved IPs and the deallocates given the container id
+func IterateForDeallocation(reservelist []types.IPReservation, containerID string, ifName string, matchFunc func(types.IPReservation, string, string) bool) ([]types.IPReservation, net.IP, error) {
+	foundidx := -1
+	for idx, reservation := range reservelist {
+		if matchFunc(reservation, containerID, ifName) {
+			foundidx = idx
+			break
+		}
+	}
+
+	if foundidx < 0 {
+		return reservelist, nil, fmt.Errorf("did not find reserved IP for container %v", containerID)
+	}
+
+	ip := reservelist[foundidx].IP
+	updatedreservelist := append(reservelist[:foundidx], reservelist[foundidx+1:]...)
+	return updatedreservelist, ip, nil
+}
+
+// IterateForAssignment iterates given an IP/IPNet and a list of reservations, finding the first free IP
+func IterateForAssignment(ipnet net.IPNet, rangeStart net.IP, rangeEnd net.IP, reservelist []types.IPReservation, excludeRanges []string, containerID string, ifName string, podRef string) (net.IP, []types.IPReservation, error) {
+	firstIP := ipnet.IP
+	if rangeStart != nil {
+		firstIP = rangeStart
+	}
+
+	lastIP := lastIPInRange(ipnet)
+	if rangeEnd != nil {
+		lastIP = rangeEnd
+	}
+
+	reserved := make(map[string]bool)
+	for _, r := range reservelist {
+		reserved[r.IP.String()] = true
+	}
+
+	for ip := cloneIP(firstIP); ipnet.Contains(ip) && compareIPs(ip, lastIP) <= 0; ip = nextIP(ip) {
+		if reserved[ip.String()] {
+			continue
+		}
+		if isExcluded(ip, excludeRanges) {
+			continue
+		}
+		newReservation := types.IPReservation{IP: cloneIP(ip), ContainerID: containerID, IfName: ifName, PodRef: podRef}
+		return ip, append(reservelist, newReservation), nil
+	}
+
+	return nil, reservelist, fmt.Errorf("no available IP addresses in range %s", ipnet.String())
+}
+
+func isExcluded(ip net.IP, excludeRanges []string) bool {
+	for _, cidr := range excludeRanges {
+		_, excludedNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			continue
+		}
+		if excludedNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func cloneIP(ip net.IP) net.IP {
+	dup := make(net.IP, len(ip))
+	copy(dup, ip)
+	return dup
+}
+
+func nextIP(ip net.IP) net.IP {
+	next := cloneIP(ip)
+	for i := len(next) - 1; i >= 0; i-- {
+		next[i]++
+		if next[i] != 0 {
+			break
+		}
+	}
+	return next
+}
+
+func compareIPs(a, b net.IP) int {
+	a16 := a.To16()
+	b16 := b.To16()
+	for i := range a16 {
+		if a16[i] != b16[i] {
+			if a16[i] < b16[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func lastIPInRange(ipnet net.IPNet) net.IP {
+	last := cloneIP(ipnet.IP)
+	for i := range last {
+		last[i] |= ^ipnet.Mask[i]
+	}
+	return last
+}
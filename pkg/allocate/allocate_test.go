@@ -0,0 +1,79 @@
+package allocate
+
+import (
+	"net"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/dougbtv/whereabouts/pkg/types"
+)
+
+func TestAllocate(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "allocate")
+}
+
+func rangeConf(cidr string) types.RangeConfiguration {
+	return types.RangeConfiguration{Range: cidr}
+}
+
+var _ = Describe("AssignIP/DeallocateIP", func() {
+	It("distinguishes interfaces on the same container", func() {
+		conf := rangeConf("10.0.0.0/29")
+
+		ip1, reservelist, err := AssignIP(conf, nil, "pod1", "eth0", "")
+		Expect(err).NotTo(HaveOccurred())
+		ip2, reservelist, err := AssignIP(conf, reservelist, "pod1", "net1", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(ip1.IP.Equal(ip2.IP)).To(BeFalse())
+		Expect(reservelist).To(HaveLen(2))
+	})
+
+	It("deallocates by matching on ifName", func() {
+		reservelist := []types.IPReservation{
+			{IP: net.ParseIP("10.0.0.1"), ContainerID: "pod1", IfName: "eth0"},
+			{IP: net.ParseIP("10.0.0.2"), ContainerID: "pod1", IfName: "net1"},
+		}
+
+		updated, err := DeallocateIP("10.0.0.0/29", reservelist, "pod1", "net1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updated).To(HaveLen(1))
+		Expect(updated[0].IfName).To(Equal("eth0"))
+	})
+
+	It("errors deallocating a containerID/ifName pair with no reservation", func() {
+		reservelist := []types.IPReservation{
+			{IP: net.ParseIP("10.0.0.1"), ContainerID: "pod1", IfName: "eth0"},
+		}
+
+		_, err := DeallocateIP("10.0.0.0/29", reservelist, "pod1", "net1")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("matches a pre-migration record (no IfName) on containerID alone", func() {
+		reservelist := []types.IPReservation{
+			{IP: net.ParseIP("10.0.0.1"), ContainerID: "pod1", IfName: ""},
+		}
+
+		updated, err := DeallocateIP("10.0.0.0/29", reservelist, "pod1", "net1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updated).To(BeEmpty())
+	})
+
+	It("skips reserved and excluded IPs when iterating for assignment", func() {
+		_, ipnet, err := net.ParseCIDR("10.0.0.0/29")
+		Expect(err).NotTo(HaveOccurred())
+
+		reservelist := []types.IPReservation{
+			{IP: net.ParseIP("10.0.0.0"), ContainerID: "other"},
+		}
+		excludeRanges := []string{"10.0.0.1/32"}
+
+		ip, _, err := IterateForAssignment(*ipnet, nil, nil, reservelist, excludeRanges, "pod1", "eth0", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ip.String()).To(Equal("10.0.0.2"))
+	})
+})
@@ -0,0 +1,175 @@
+// Package config loads and normalizes the IPAM configuration passed to
+// whereabouts by the CNI runtime, optionally merging in a node-local
+// flat-file configuration.
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"strings"
+
+	"github.com/dougbtv/whereabouts/pkg/logging"
+	"github.com/dougbtv/whereabouts/pkg/types"
+)
+
+// LoadIPAMConfig creates an IPAMConfig from the given JSON CNI config, merging in
+// any flat-file configuration referenced by `configuration_path`
+func LoadIPAMConfig(bytes []byte, envArgs string) (*types.IPAMConfig, string, error) {
+	n := types.Net{}
+	if err := json.Unmarshal(bytes, &n); err != nil {
+		return nil, "", fmt.Errorf("LoadIPAMConfig: error unmarshalling CNI config: %v", err)
+	}
+
+	if n.IPAM == nil {
+		return nil, "", fmt.Errorf("LoadIPAMConfig: 'ipam' key is missing from CNI config")
+	}
+	n.IPAM.Name = n.Name
+
+	if n.IPAM.ConfigurationPath != "" {
+		if err := mergeFlatFileConfig(n.IPAM); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if err := finalizeIPAMConfig(n.IPAM); err != nil {
+		return nil, "", err
+	}
+
+	return n.IPAM, n.CNIVersion, nil
+}
+
+// LoadDaemonConfig creates an IPAMConfig from the whereaboutsd flat-file
+// config, which (unlike the CNI config LoadIPAMConfig parses) has no
+// surrounding `{"name":..., "ipam": {...}}` envelope: it's just the
+// IPAMConfig fields at the top level.
+func LoadDaemonConfig(bytes []byte) (*types.IPAMConfig, error) {
+	ipamConf := &types.IPAMConfig{}
+	if err := json.Unmarshal(bytes, ipamConf); err != nil {
+		return nil, fmt.Errorf("LoadDaemonConfig: error unmarshalling config: %v", err)
+	}
+
+	if err := finalizeIPAMConfig(ipamConf); err != nil {
+		return nil, err
+	}
+
+	return ipamConf, nil
+}
+
+// finalizeIPAMConfig applies the normalization and defaulting shared by
+// LoadIPAMConfig and LoadDaemonConfig: log setup, range normalization,
+// gateway parsing, and lease/backoff defaults. pool_name is deliberately
+// left unresolved here; it's resolved later by storage.IPManagementWithStore,
+// once a Store is available, via the flat-file Pools map or a PoolNameResolver.
+func finalizeIPAMConfig(ipamConf *types.IPAMConfig) error {
+	if ipamConf.LogFile != "" {
+		logging.SetLogFile(ipamConf.LogFile)
+	}
+	if ipamConf.LogLevel != "" {
+		logging.SetLogLevel(ipamConf.LogLevel)
+	}
+
+	if ipamConf.Range != "" {
+		normalizedRange, rangeStart, rangeEnd, err := normalizeRange(ipamConf.Range)
+		if err != nil {
+			return err
+		}
+		ipamConf.Range = normalizedRange
+		if ipamConf.RangeStart == nil {
+			ipamConf.RangeStart = rangeStart
+		}
+		if ipamConf.RangeEnd == nil {
+			ipamConf.RangeEnd = rangeEnd
+		}
+	}
+
+	for i, r := range ipamConf.Ranges {
+		normalizedRange, rangeStart, rangeEnd, err := normalizeRange(r.Range)
+		if err != nil {
+			return err
+		}
+		ipamConf.Ranges[i].Range = normalizedRange
+		if ipamConf.Ranges[i].RangeStart == nil {
+			ipamConf.Ranges[i].RangeStart = rangeStart
+		}
+		if ipamConf.Ranges[i].RangeEnd == nil {
+			ipamConf.Ranges[i].RangeEnd = rangeEnd
+		}
+	}
+
+	if ipamConf.GatewayStr != "" {
+		ipamConf.Gateway = net.ParseIP(ipamConf.GatewayStr)
+	}
+
+	if ipamConf.LeaseDuration == 0 {
+		ipamConf.LeaseDuration = types.DefaultLeaseDuration
+	}
+	if ipamConf.Backoff == 0 {
+		ipamConf.Backoff = types.DefaultBackoff
+	}
+
+	return nil
+}
+
+// normalizeRange parses a range of the form "start-end/prefixlen" or a plain CIDR
+// and returns the normalized CIDR along with the (optional) start/end IPs
+func normalizeRange(r string) (string, net.IP, net.IP, error) {
+	var start, end net.IP
+
+	cidr := r
+	if idx := strings.Index(r, "-"); idx >= 0 {
+		slashIdx := strings.Index(r, "/")
+		if slashIdx < 0 {
+			return "", nil, nil, fmt.Errorf("normalizeRange: invalid range %q, missing prefix length", r)
+		}
+		start = net.ParseIP(r[:idx])
+		end = net.ParseIP(r[idx+1 : slashIdx])
+		cidr = start.String() + r[slashIdx:]
+	}
+
+	_, ipnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("normalizeRange: invalid range %q: %v", r, err)
+	}
+
+	return ipnet.String(), start, end, nil
+}
+
+// mergeFlatFileConfig reads the flat-file config at ipamConf.ConfigurationPath and
+// fills in any fields that were left unset in ipamConf, which always takes precedence
+func mergeFlatFileConfig(ipamConf *types.IPAMConfig) error {
+	fileBytes, err := ioutil.ReadFile(ipamConf.ConfigurationPath)
+	if err != nil {
+		return fmt.Errorf("mergeFlatFileConfig: error reading %s: %v", ipamConf.ConfigurationPath, err)
+	}
+
+	var fileConf types.IPAMConfig
+	if err := json.Unmarshal(fileBytes, &fileConf); err != nil {
+		return fmt.Errorf("mergeFlatFileConfig: error unmarshalling %s: %v", ipamConf.ConfigurationPath, err)
+	}
+
+	if ipamConf.Datastore == "" {
+		ipamConf.Datastore = fileConf.Datastore
+	}
+	if ipamConf.LogFile == "" {
+		ipamConf.LogFile = fileConf.LogFile
+	}
+	if ipamConf.LogLevel == "" {
+		ipamConf.LogLevel = fileConf.LogLevel
+	}
+	if ipamConf.GatewayStr == "" {
+		ipamConf.GatewayStr = fileConf.GatewayStr
+	}
+	if ipamConf.Kubernetes.KubeConfigPath == "" {
+		ipamConf.Kubernetes = fileConf.Kubernetes
+	}
+	if ipamConf.EtcdHost == "" {
+		ipamConf.EtcdHost = fileConf.EtcdHost
+	}
+	if ipamConf.Pools == nil {
+		ipamConf.Pools = fileConf.Pools
+	}
+
+	return nil
+}
@@ -95,4 +95,55 @@ var _ = Describe("Allocation operations", func() {
 
 	})
 
+	It("normalizes each entry of a multi-range config, not just the singular range", func() {
+
+		conf := `{
+      "cniVersion": "0.3.1",
+      "name": "mynet",
+      "type": "ipvlan",
+      "master": "foo0",
+        "ipam": {
+          "type": "whereabouts",
+          "ranges": [
+            {"range": "192.168.1.5-192.168.1.25/24"},
+            {"range": "2001:db8::5-2001:db8::25/64"}
+          ]
+        }
+      }`
+
+		ipamconfig, _, err := LoadIPAMConfig([]byte(conf), "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ipamconfig.Ranges).To(HaveLen(2))
+		Expect(ipamconfig.Ranges[0].Range).To(Equal("192.168.1.0/24"))
+		Expect(ipamconfig.Ranges[0].RangeStart).To(Equal(net.ParseIP("192.168.1.5")))
+		Expect(ipamconfig.Ranges[0].RangeEnd).To(Equal(net.ParseIP("192.168.1.25")))
+		Expect(ipamconfig.Ranges[1].Range).To(Equal("2001:db8::/64"))
+		Expect(ipamconfig.Ranges[1].RangeStart).To(Equal(net.ParseIP("2001:db8::5")))
+		Expect(ipamconfig.Ranges[1].RangeEnd).To(Equal(net.ParseIP("2001:db8::25")))
+
+	})
+
+	It("loads the flat (un-enveloped) daemon config", func() {
+
+		conf := `{
+      "datastore": "kubernetes",
+      "kubernetes": {
+        "kubeconfig": "/etc/cni/net.d/whereabouts.d/whereabouts.kubeconfig"
+      },
+      "range": "192.168.1.5-192.168.1.25/24",
+      "log_level": "debug"
+    }`
+
+		ipamconfig, err := LoadDaemonConfig([]byte(conf))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ipamconfig.Datastore).To(Equal("kubernetes"))
+		Expect(ipamconfig.Kubernetes.KubeConfigPath).To(Equal("/etc/cni/net.d/whereabouts.d/whereabouts.kubeconfig"))
+		Expect(ipamconfig.Range).To(Equal("192.168.1.0/24"))
+		Expect(ipamconfig.RangeStart).To(Equal(net.ParseIP("192.168.1.5")))
+		Expect(ipamconfig.RangeEnd).To(Equal(net.ParseIP("192.168.1.25")))
+		Expect(ipamconfig.LeaseDuration).To(Equal(10))
+		Expect(ipamconfig.Backoff).To(Equal(1000))
+
+	})
+
 })
@@ -0,0 +1,98 @@
+// Package daemon implements the whereaboutsd gRPC server: the allocation
+// loop from pkg/storage, running inside a long-lived node-local process
+// instead of once per CNI invocation. Holding the datastore connection
+// (etcd/k8s client, informer caches, leader lock) across invocations avoids
+// per-call TLS handshakes and client startup on pod-churn nodes.
+package daemon
+
+import (
+	"context"
+
+	"github.com/dougbtv/whereabouts/pkg/rpc"
+	"github.com/dougbtv/whereabouts/pkg/storage"
+	"github.com/dougbtv/whereabouts/pkg/types"
+)
+
+// Server implements rpc.WhereaboutsServer against a single long-lived Store connection
+type Server struct {
+	ipamConf types.IPAMConfig
+	ipam     storage.Store
+}
+
+// NewServer opens the datastore connection described by ipamConf and returns a
+// Server ready to be registered with rpc.RegisterWhereaboutsServer
+func NewServer(ctx context.Context, ipamConf types.IPAMConfig) (*Server, error) {
+	ipam, err := storage.NewIPAMStore(ctx, ipamConf, "")
+	if err != nil {
+		return nil, err
+	}
+	return &Server{ipamConf: ipamConf, ipam: ipam}, nil
+}
+
+// Close releases the underlying datastore connection
+func (s *Server) Close(ctx context.Context) error {
+	return s.ipam.Close(ctx)
+}
+
+// configFor returns the IPAMConfig to use for a single RPC call: a copy of
+// the daemon's startup config, with PoolName overridden by a per-request
+// pool_name (letting one whereaboutsd serve more than one pool, since NADs
+// can each cite a different pool_name). An explicit per-request pool_name is
+// authoritative, so it also forces resolution the same way force_pool_name
+// does, rather than being silently shadowed by the daemon's static Range.
+func (s *Server) configFor(poolName string) types.IPAMConfig {
+	conf := s.ipamConf
+	if poolName != "" {
+		conf.PoolName = poolName
+		conf.ForcePoolName = true
+	}
+	return conf
+}
+
+// Allocate assigns IP(s) for the given containerID/ifName/podRef
+func (s *Server) Allocate(ctx context.Context, req *rpc.AllocateRequest) (*rpc.AllocateResponse, error) {
+	conf := s.configFor(req.PoolName)
+
+	newips, err := storage.IPManagementWithStore(types.Allocate, conf, s.ipam, req.ContainerId, req.IfName, req.PodRef)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &rpc.AllocateResponse{Gateway: conf.GatewayStr}
+	for _, ipnet := range newips {
+		resp.Ips = append(resp.Ips, ipnet.String())
+	}
+	for _, route := range conf.Routes {
+		resp.Routes = append(resp.Routes, route.Dst.String())
+	}
+	return resp, nil
+}
+
+// Deallocate releases the (containerID, ifName) reservation
+func (s *Server) Deallocate(ctx context.Context, req *rpc.DeallocateRequest) (*rpc.DeallocateResponse, error) {
+	conf := s.configFor(req.PoolName)
+	if _, err := storage.IPManagementWithStore(types.Deallocate, conf, s.ipam, req.ContainerId, req.IfName, ""); err != nil {
+		return nil, err
+	}
+	return &rpc.DeallocateResponse{}, nil
+}
+
+// IsAllocated reports whether the (containerID, ifName) tuple currently holds a reservation
+func (s *Server) IsAllocated(ctx context.Context, req *rpc.IsAllocatedRequest) (*rpc.IsAllocatedResponse, error) {
+	conf := s.configFor(req.PoolName)
+
+	ranges, err := storage.ResolveRanges(ctx, s.ipam, conf)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range ranges {
+		allocated, err := storage.IsAllocated(ctx, s.ipam, r.Range, req.ContainerId, req.IfName)
+		if err != nil {
+			return nil, err
+		}
+		if allocated {
+			return &rpc.IsAllocatedResponse{Allocated: true}, nil
+		}
+	}
+	return &rpc.IsAllocatedResponse{Allocated: false}, nil
+}
@@ -0,0 +1,62 @@
+package daemon
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/dougbtv/whereabouts/pkg/rpc"
+	"github.com/dougbtv/whereabouts/pkg/storage"
+	"github.com/dougbtv/whereabouts/pkg/types"
+)
+
+func TestDaemon(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "daemon")
+}
+
+var _ = Describe("Server.IsAllocated", func() {
+	var path string
+	var srv *Server
+
+	AfterEach(func() {
+		Expect(srv.Close(context.Background())).To(Succeed())
+		os.Remove(path)
+	})
+
+	It("finds a reservation held in a range other than Ranges[0]", func() {
+		path = "/tmp/whereabouts-daemon-test.db"
+
+		ipamConf := types.IPAMConfig{
+			Datastore: types.DatastoreLocal,
+			Ranges: []types.RangeConfiguration{
+				{Range: "10.10.0.0/24"},
+				{Range: "10.20.0.0/24"},
+			},
+			LocalDatastorePath: path,
+			RequestTimeout:     5,
+		}
+
+		var err error
+		srv, err = NewServer(context.Background(), ipamConf)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = storage.IPManagementWithStore(types.Allocate, types.IPAMConfig{
+			Datastore:          types.DatastoreLocal,
+			Range:              "10.20.0.0/24",
+			LocalDatastorePath: path,
+			RequestTimeout:     5,
+		}, srv.ipam, "container1", "eth0", "default/pod1")
+		Expect(err).NotTo(HaveOccurred())
+
+		resp, err := srv.IsAllocated(context.Background(), &rpc.IsAllocatedRequest{
+			ContainerId: "container1",
+			IfName:      "eth0",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.Allocated).To(BeTrue())
+	})
+})
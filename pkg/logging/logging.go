@@ -0,0 +1,91 @@
+// Package logging is a simple logging wrapper for whereabouts, writing to
+// either stderr or a configured log file at a configured verbosity.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+)
+
+// Level is the log urgency level
+type Level string
+
+const (
+	debugLevel   Level = "debug"
+	verboseLevel Level = "verbose"
+	errorLevel   Level = "error"
+	panicLevel   Level = "panic"
+)
+
+var (
+	logLevel = errorLevel
+	logger   = log.New(os.Stderr, "", log.LstdFlags)
+)
+
+func levelToInt(l Level) int {
+	switch l {
+	case debugLevel:
+		return 0
+	case verboseLevel:
+		return 1
+	case errorLevel:
+		return 2
+	case panicLevel:
+		return 3
+	}
+	return 2
+}
+
+// SetLogLevel sets the log verbosity, one of: debug, verbose, error, panic
+func SetLogLevel(level string) {
+	switch Level(level) {
+	case debugLevel, verboseLevel, errorLevel, panicLevel:
+		logLevel = Level(level)
+	}
+}
+
+// SetLogFile redirects output to the given file path
+func SetLogFile(path string) {
+	if path == "" {
+		return
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return
+	}
+	setOutput(f)
+}
+
+func setOutput(w io.Writer) {
+	logger = log.New(w, "", log.LstdFlags)
+}
+
+func printf(level Level, format string, a ...interface{}) {
+	if levelToInt(level) < levelToInt(logLevel) {
+		return
+	}
+	logger.Printf("[%s] %s", level, fmt.Sprintf(format, a...))
+}
+
+// Debugf logs a debug-level message
+func Debugf(format string, a ...interface{}) {
+	printf(debugLevel, format, a...)
+}
+
+// Verbosef logs a verbose-level message
+func Verbosef(format string, a ...interface{}) {
+	printf(verboseLevel, format, a...)
+}
+
+// Errorf logs an error-level message and returns it as an error
+func Errorf(format string, a ...interface{}) error {
+	printf(errorLevel, format, a...)
+	return fmt.Errorf(format, a...)
+}
+
+// Panicf logs a panic-level message
+func Panicf(format string, a ...interface{}) {
+	printf(panicLevel, format, a...)
+}
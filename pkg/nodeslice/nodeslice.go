@@ -0,0 +1,133 @@
+// Package nodeslice implements the block-assignment logic for carving a
+// CIDRPool's parent range into one fixed-size block per node: Reconcile and
+// BlockForNode are pure functions over types.CIDRPool. Assignments are sticky
+// across reconciles: a node keeps its block as long as it keeps existing, so
+// pods on other nodes never see their range move underneath them when the
+// node set changes.
+//
+// Nothing in this tree calls Reconcile yet: there is no controller watching
+// CIDRPools/Nodes, no Store backend implements storage.NodeSlicer, and
+// types.CIDRPool isn't registered as a CRD (no TypeMeta/ObjectMeta). Wiring
+// IPAMConfig.NodeSliceName up end to end needs all three; for now this
+// package is a reconciliation helper that a future controller can call into.
+package nodeslice
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+	"sort"
+
+	"github.com/dougbtv/whereabouts/pkg/types"
+)
+
+// Reconcile assigns a block to every name in nodeNames, preserving the
+// existing assignment (if any) for nodes that are still present, freeing the
+// blocks of nodes that are no longer present, and deterministically handing
+// newly-seen nodes the lowest free block. It returns the status to be
+// written back to the CIDRPool CR.
+func Reconcile(pool *types.CIDRPool, nodeNames []string) (types.CIDRPoolStatus, error) {
+	blocks, err := subnets(pool.Spec.CIDR, pool.Spec.PerNodeBlockSize, pool.Spec.Exclusions)
+	if err != nil {
+		return types.CIDRPoolStatus{}, err
+	}
+
+	wantNodes := make(map[string]bool, len(nodeNames))
+	for _, n := range nodeNames {
+		wantNodes[n] = true
+	}
+
+	used := make(map[string]bool, len(blocks))
+	var kept []types.NodeBlockAllocation
+	for _, a := range pool.Status.Allocations {
+		if !wantNodes[a.NodeName] {
+			continue // node no longer exists: free its block for reassignment
+		}
+		kept = append(kept, a)
+		used[a.Block] = true
+		delete(wantNodes, a.NodeName)
+	}
+
+	var unassigned []string
+	for n := range wantNodes {
+		unassigned = append(unassigned, n)
+	}
+	sort.Strings(unassigned) // deterministic assignment order across reconciles
+
+	blockIdx := 0
+	for _, n := range unassigned {
+		for blockIdx < len(blocks) && used[blocks[blockIdx]] {
+			blockIdx++
+		}
+		if blockIdx >= len(blocks) {
+			return types.CIDRPoolStatus{}, fmt.Errorf("nodeslice: pool %q is exhausted: no /%d block left for node %q", pool.Name, pool.Spec.PerNodeBlockSize, n)
+		}
+		kept = append(kept, types.NodeBlockAllocation{NodeName: n, Block: blocks[blockIdx]})
+		used[blocks[blockIdx]] = true
+		blockIdx++
+	}
+
+	sort.Slice(kept, func(i, j int) bool { return kept[i].NodeName < kept[j].NodeName })
+	return types.CIDRPoolStatus{Allocations: kept}, nil
+}
+
+// BlockForNode returns the block assigned to nodeName by the most recent Reconcile
+func BlockForNode(pool *types.CIDRPool, nodeName string) (string, error) {
+	for _, a := range pool.Status.Allocations {
+		if a.NodeName == nodeName {
+			return a.Block, nil
+		}
+	}
+	return "", fmt.Errorf("nodeslice: pool %q has no block assigned to node %q", pool.Name, nodeName)
+}
+
+// subnets enumerates every blockSize-prefix subnet of parentCIDR, in address
+// order, skipping any that overlaps one of the exclusions
+func subnets(parentCIDR string, blockSize int, exclusions []string) ([]string, error) {
+	_, parent, err := net.ParseCIDR(parentCIDR)
+	if err != nil {
+		return nil, fmt.Errorf("nodeslice: invalid CIDR %q: %v", parentCIDR, err)
+	}
+
+	ones, bits := parent.Mask.Size()
+	if blockSize < ones || blockSize > bits {
+		return nil, fmt.Errorf("nodeslice: perNodeBlockSize /%d is not a valid sub-block of /%d", blockSize, ones)
+	}
+
+	var excluded []*net.IPNet
+	for _, e := range exclusions {
+		_, n, err := net.ParseCIDR(e)
+		if err != nil {
+			return nil, fmt.Errorf("nodeslice: invalid exclusion %q: %v", e, err)
+		}
+		excluded = append(excluded, n)
+	}
+
+	ipLen := len(parent.IP)
+	base := new(big.Int).SetBytes(parent.IP)
+	stride := new(big.Int).Lsh(big.NewInt(1), uint(bits-blockSize))
+	blockCount := 1 << uint(blockSize-ones)
+
+	var out []string
+	for i := 0; i < blockCount; i++ {
+		offset := new(big.Int).Mul(stride, big.NewInt(int64(i)))
+		blockIP := make(net.IP, ipLen)
+		new(big.Int).Add(base, offset).FillBytes(blockIP)
+
+		block := &net.IPNet{IP: blockIP, Mask: net.CIDRMask(blockSize, bits)}
+		if overlapsAny(block, excluded) {
+			continue
+		}
+		out = append(out, block.String())
+	}
+	return out, nil
+}
+
+func overlapsAny(block *net.IPNet, excluded []*net.IPNet) bool {
+	for _, e := range excluded {
+		if block.Contains(e.IP) || e.Contains(block.IP) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package nodeslice
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/dougbtv/whereabouts/pkg/types"
+)
+
+func TestNodeslice(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "nodeslice")
+}
+
+func testPool() *types.CIDRPool {
+	return &types.CIDRPool{
+		Name: "pool1",
+		Spec: types.CIDRPoolSpec{
+			CIDR:             "10.0.0.0/24",
+			PerNodeBlockSize: 28,
+		},
+	}
+}
+
+var _ = Describe("Reconcile/BlockForNode", func() {
+	It("assigns a block per node", func() {
+		pool := testPool()
+
+		status, err := Reconcile(pool, []string{"node-b", "node-a"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.Allocations).To(HaveLen(2))
+
+		blocks := make(map[string]string)
+		for _, a := range status.Allocations {
+			blocks[a.NodeName] = a.Block
+		}
+		Expect(blocks["node-a"]).NotTo(BeEmpty())
+		Expect(blocks["node-b"]).NotTo(BeEmpty())
+		Expect(blocks["node-a"]).NotTo(Equal(blocks["node-b"]))
+	})
+
+	It("is sticky across calls", func() {
+		pool := testPool()
+
+		first, err := Reconcile(pool, []string{"node-a", "node-b"})
+		Expect(err).NotTo(HaveOccurred())
+		pool.Status = first
+
+		second, err := Reconcile(pool, []string{"node-a", "node-b", "node-c"})
+		Expect(err).NotTo(HaveOccurred())
+
+		blocksByNode := make(map[string]string)
+		for _, a := range second.Allocations {
+			blocksByNode[a.NodeName] = a.Block
+		}
+		for _, a := range first.Allocations {
+			Expect(blocksByNode[a.NodeName]).To(Equal(a.Block))
+		}
+		Expect(blocksByNode["node-c"]).NotTo(BeEmpty())
+	})
+
+	It("frees the block of a removed node", func() {
+		pool := testPool()
+
+		first, err := Reconcile(pool, []string{"node-a", "node-b"})
+		Expect(err).NotTo(HaveOccurred())
+		pool.Status = first
+
+		second, err := Reconcile(pool, []string{"node-c"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(second.Allocations).To(HaveLen(1))
+		Expect(second.Allocations[0].NodeName).To(Equal("node-c"))
+	})
+
+	It("errors when there aren't enough blocks for every node", func() {
+		pool := &types.CIDRPool{
+			Name: "tiny",
+			Spec: types.CIDRPoolSpec{
+				CIDR:             "10.0.0.0/31",
+				PerNodeBlockSize: 31,
+			},
+		}
+
+		_, err := Reconcile(pool, []string{"node-a", "node-b"})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("looks up the block assigned to a node, and errors for an unassigned one", func() {
+		pool := testPool()
+		status, err := Reconcile(pool, []string{"node-a"})
+		Expect(err).NotTo(HaveOccurred())
+		pool.Status = status
+
+		block, err := BlockForNode(pool, "node-a")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(block).To(Equal(status.Allocations[0].Block))
+
+		_, err = BlockForNode(pool, "node-missing")
+		Expect(err).To(HaveOccurred())
+	})
+})
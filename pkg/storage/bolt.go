@@ -0,0 +1,162 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/dougbtv/whereabouts/pkg/logging"
+	"github.com/dougbtv/whereabouts/pkg/types"
+)
+
+const (
+	// idsBucketName holds reservationKey(containerID, ifName) -> JSON-encoded
+	// []net.IP, per range bucket
+	idsBucketName = "ids"
+	// idKeySep separates containerID from ifName within an idsBucket key; NUL
+	// can't appear in either, so it can always be split back out unambiguously
+	idKeySep = "\x00"
+)
+
+// reservationKey builds the idsBucket key for a (containerID, ifName) pair, so
+// that two interfaces on the same pod drawing from the same range don't
+// collide under a single containerID entry
+func reservationKey(containerID, ifName string) string {
+	return containerID + idKeySep + ifName
+}
+
+// splitReservationKey recovers the (containerID, ifName) pair encoded by reservationKey
+func splitReservationKey(key string) (string, string) {
+	idx := strings.Index(key, idKeySep)
+	if idx < 0 {
+		// Pre-migration key: written before ifName existed, so it's a bare containerID
+		return key, ""
+	}
+	return key[:idx], key[idx+len(idKeySep):]
+}
+
+// BoltIPAM is a Store implementation backed by a local boltdb file, giving
+// single-node / edge deployments a zero-dependency datastore that doesn't
+// require etcd or a reachable kube-apiserver at CNI invocation time
+type BoltIPAM struct {
+	db *bolt.DB
+}
+
+// NewBoltIPAM opens (creating if necessary) the boltdb file configured by
+// ipamConf.LocalDatastorePath, defaulting to types.DefaultLocalDatastorePath.
+// Locking is handled by boltdb's own file lock, so LockRequestTimeout maps
+// directly to the bolt.Open timeout.
+func NewBoltIPAM(ipamConf types.IPAMConfig) (*BoltIPAM, error) {
+	path := ipamConf.LocalDatastorePath
+	if path == "" {
+		path = types.DefaultLocalDatastorePath
+	}
+
+	timeout := time.Duration(ipamConf.LockRequestTimeout) * time.Second
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: timeout})
+	if err != nil {
+		return nil, logging.Errorf("BoltIPAM: error opening %s: %v", path, err)
+	}
+
+	return &BoltIPAM{db: db}, nil
+}
+
+// Status checks that the boltdb file is reachable
+func (b *BoltIPAM) Status(ctx context.Context) error {
+	return b.db.View(func(tx *bolt.Tx) error {
+		return nil
+	})
+}
+
+// Close closes the underlying boltdb file
+func (b *BoltIPAM) Close(ctx context.Context) error {
+	return b.db.Close()
+}
+
+// GetIPPool returns a snapshot of the allocations for ipRange, creating its
+// bucket layout (one top-level bucket per range, containing an "ids"
+// sub-bucket) if this is the first time the range has been seen
+func (b *BoltIPAM) GetIPPool(ctx context.Context, ipRange string) (IPPool, error) {
+	var reservelist []types.IPReservation
+
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		rangeBucket, err := tx.CreateBucketIfNotExists([]byte(ipRange))
+		if err != nil {
+			return err
+		}
+		idsBucket, err := rangeBucket.CreateBucketIfNotExists([]byte(idsBucketName))
+		if err != nil {
+			return err
+		}
+
+		return idsBucket.ForEach(func(key, encodedIPs []byte) error {
+			var ips []net.IP
+			if err := json.Unmarshal(encodedIPs, &ips); err != nil {
+				return err
+			}
+			containerID, ifName := splitReservationKey(string(key))
+			for _, ip := range ips {
+				reservelist = append(reservelist, types.IPReservation{IP: ip, ContainerID: containerID, IfName: ifName})
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, logging.Errorf("BoltIPAM: error reading pool %s: %v", ipRange, err)
+	}
+
+	return &boltIPPool{db: b.db, ipRange: ipRange, reservelist: reservelist}, nil
+}
+
+// boltIPPool is a single range's allocation snapshot, backed by boltdb
+type boltIPPool struct {
+	db          *bolt.DB
+	ipRange     string
+	reservelist []types.IPReservation
+}
+
+// Allocations returns the snapshot of reservations read by GetIPPool
+func (p *boltIPPool) Allocations() []types.IPReservation {
+	return p.reservelist
+}
+
+// Update rewrites the range's "ids" sub-bucket with the given reservations:
+// reservationKey(containerID, ifName) -> JSON-encoded []net.IP
+func (p *boltIPPool) Update(ctx context.Context, reservations []types.IPReservation) error {
+	return p.db.Update(func(tx *bolt.Tx) error {
+		rangeBucket, err := tx.CreateBucketIfNotExists([]byte(p.ipRange))
+		if err != nil {
+			return err
+		}
+
+		if err := rangeBucket.DeleteBucket([]byte(idsBucketName)); err != nil && err != bolt.ErrBucketNotFound {
+			return err
+		}
+		idsBucket, err := rangeBucket.CreateBucket([]byte(idsBucketName))
+		if err != nil {
+			return err
+		}
+
+		ipsByKey := make(map[string][]net.IP)
+		for _, reservation := range reservations {
+			key := reservationKey(reservation.ContainerID, reservation.IfName)
+			ipsByKey[key] = append(ipsByKey[key], reservation.IP)
+		}
+
+		for key, ips := range ipsByKey {
+			encoded, err := json.Marshal(ips)
+			if err != nil {
+				return err
+			}
+			if err := idsBucket.Put([]byte(key), encoded); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
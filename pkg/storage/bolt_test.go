@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"context"
+	"net"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/dougbtv/whereabouts/pkg/types"
+)
+
+var _ = Describe("BoltIPAM", func() {
+	It("keeps distinct IfName/IP reservations for two interfaces on the same pod", func() {
+		path := "/tmp/whereabouts-bolt-test.db"
+		defer os.Remove(path)
+
+		b, err := NewBoltIPAM(types.IPAMConfig{LocalDatastorePath: path})
+		Expect(err).NotTo(HaveOccurred())
+		defer b.Close(context.Background())
+
+		const ipRange = "10.10.0.0/24"
+
+		pool, err := b.GetIPPool(context.Background(), ipRange)
+		Expect(err).NotTo(HaveOccurred())
+
+		reservations := []types.IPReservation{
+			{IP: net.ParseIP("10.10.0.1"), ContainerID: "pod1", IfName: "eth0"},
+			{IP: net.ParseIP("10.10.0.2"), ContainerID: "pod1", IfName: "net1"},
+		}
+		Expect(pool.Update(context.Background(), reservations)).To(Succeed())
+
+		pool, err = b.GetIPPool(context.Background(), ipRange)
+		Expect(err).NotTo(HaveOccurred())
+
+		got := pool.Allocations()
+		Expect(got).To(HaveLen(2))
+
+		byIfName := make(map[string]types.IPReservation)
+		for _, r := range got {
+			byIfName[r.IfName] = r
+		}
+
+		eth0, ok := byIfName["eth0"]
+		Expect(ok).To(BeTrue())
+		Expect(eth0.IP.Equal(net.ParseIP("10.10.0.1"))).To(BeTrue())
+
+		net1, ok := byIfName["net1"]
+		Expect(ok).To(BeTrue())
+		Expect(net1.IP.Equal(net.ParseIP("10.10.0.2"))).To(BeTrue())
+
+		Expect(eth0.ContainerID).To(Equal("pod1"))
+		Expect(net1.ContainerID).To(Equal("pod1"))
+	})
+
+	It("round-trips reservationKey, falling back to a bare containerID for legacy keys", func() {
+		containerID, ifName := splitReservationKey(reservationKey("abc123", "eth0"))
+		Expect(containerID).To(Equal("abc123"))
+		Expect(ifName).To(Equal("eth0"))
+
+		// Pre-migration keys (written before IfName existed) are bare containerIDs.
+		containerID, ifName = splitReservationKey("legacycontainerid")
+		Expect(containerID).To(Equal("legacycontainerid"))
+		Expect(ifName).To(Equal(""))
+	})
+})
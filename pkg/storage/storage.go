@@ -30,66 +30,224 @@ type Store interface {
 	Close(ctx context.Context) error
 }
 
-// IPManagement manages ip allocation and deallocation from a storage perspective
-func IPManagement(mode int, ipamConf types.IPAMConfig, containerID string, podRef string) (net.IPNet, error) {
-
-	logging.Debugf("IPManagement -- mode: %v / host: %v / containerID: %v / podRef: %v", mode, ipamConf.EtcdHost, containerID, podRef)
-
-	var newip net.IPNet
-	// Skip invalid modes
-	switch mode {
-	case types.Allocate, types.Deallocate:
-	default:
-		return newip, fmt.Errorf("Got an unknown mode passed to IPManagement: %v", mode)
-	}
+// NodeSlicer is implemented by Store backends that can resolve a CIDRPool by
+// name into the block assigned to a given node, using the assignment logic
+// in pkg/nodeslice. No backend implements it yet, so IPAMConfig.NodeSliceName
+// can't actually resolve at runtime today; see pkg/nodeslice's package doc.
+type NodeSlicer interface {
+	GetNodeSlice(ctx context.Context, poolName string, nodeName string) (types.RangeConfiguration, error)
+}
 
-	ctx, acquireCancel := context.WithTimeout(context.Background(), time.Duration(ipamConf.LockRequestTimeout)*time.Second)
-	defer acquireCancel()
+// PoolNameResolver is implemented by Store backends that can resolve a
+// pool_name to a concrete range via a Pool CR. None of the backends in this
+// package (etcd, kubernetes, local) implements it, so a pool_name resolves
+// only through the pool_name -> range mapping in ipamConf.Pools, populated
+// from the global flat-file config (see config.mergeFlatFileConfig).
+// IPManagementWithStore tries the flat-file map first and only falls back to
+// this interface if that lookup misses.
+type PoolNameResolver interface {
+	ResolvePoolName(ctx context.Context, poolName string) (string, error)
+}
 
+// NewIPAMStore opens a connection to the configured datastore backend. Short-lived
+// CNI invocations open one of these per call (see IPManagement); the whereaboutsd
+// daemon instead keeps one of these open across many Allocate/Deallocate calls, so
+// it doesn't pay the client/TLS/informer startup cost on every pod add/del.
+func NewIPAMStore(ctx context.Context, ipamConf types.IPAMConfig, containerID string) (Store, error) {
 	var ipam Store
-	var pool IPPool
 	var err error
 	switch ipamConf.Datastore {
 	case types.DatastoreETCD:
 		ipam, err = NewETCDIPAM(ctx, ipamConf)
 	case types.DatastoreKubernetes:
 		ipam, err = NewKubernetesIPAM(ctx, containerID, ipamConf)
+	case types.DatastoreLocal:
+		ipam, err = NewBoltIPAM(ipamConf)
+	}
+	if err != nil {
+		return nil, logging.Errorf("IPAM %s client initialization error: %v", ipamConf.Datastore, err)
 	}
+	return ipam, nil
+}
+
+// IPManagement manages ip allocation and deallocation from a storage perspective,
+// opening and closing a Store for the duration of the call
+func IPManagement(mode int, ipamConf types.IPAMConfig, containerID string, ifName string, podRef string) ([]net.IPNet, error) {
+
+	ctx, acquireCancel := context.WithTimeout(context.Background(), time.Duration(ipamConf.LockRequestTimeout)*time.Second)
+	defer acquireCancel()
+
+	ipam, err := NewIPAMStore(ctx, ipamConf, containerID)
 	if err != nil {
-		logging.Errorf("IPAM %s client initialization error: %v", ipamConf.Datastore, err)
-		return newip, fmt.Errorf("IPAM %s client initialization error: %v", ipamConf.Datastore, err)
+		return nil, err
 	}
 	defer func() {
 		ctx, releaseCancel := context.WithTimeout(context.Background(), time.Duration(ipamConf.LockRequestTimeout)*time.Second)
-		err = ipam.Close(ctx)
-		if err != nil {
+		if err := ipam.Close(ctx); err != nil {
 			logging.Errorf("error in closing ipam pool %v", err)
 		}
 		releaseCancel()
 	}()
 
+	return IPManagementWithStore(mode, ipamConf, ipam, containerID, ifName, podRef)
+}
+
+// IPManagementWithStore manages ip allocation and deallocation against an
+// already-open Store, so that a long-lived caller (such as the whereaboutsd
+// daemon) can reuse one datastore connection across many invocations instead
+// of paying per-call connection setup.
+func IPManagementWithStore(mode int, ipamConf types.IPAMConfig, ipam Store, containerID string, ifName string, podRef string) ([]net.IPNet, error) {
+
+	logging.Debugf("IPManagement -- mode: %v / host: %v / containerID: %v / ifName: %v / podRef: %v", mode, ipamConf.EtcdHost, containerID, ifName, podRef)
+
+	// Skip invalid modes
+	switch mode {
+	case types.Allocate, types.Deallocate:
+	default:
+		return nil, fmt.Errorf("Got an unknown mode passed to IPManagement: %v", mode)
+	}
+
 	ctx, ipPoolOpCancel := context.WithTimeout(context.Background(), time.Duration(ipamConf.RequestTimeout)*time.Second)
 	defer ipPoolOpCancel()
 
 	// Check our connectivity first
 	if err := ipam.Status(ctx); err != nil {
 		logging.Errorf("IPAM connectivity error: %v", err)
-		return newip, err
+		return nil, err
+	}
+
+	ranges, err := ResolveRanges(ctx, ipam, ipamConf)
+	if err != nil {
+		return nil, err
+	}
+
+	// Allocation is all-or-nothing across the configured ranges: if any range
+	// fails, roll back every IP we already assigned in this call before returning.
+	var newips []net.IPNet
+	for _, r := range ranges {
+		newip, err := ipManagementForRange(ctx, ipam, mode, r, containerID, ifName, podRef)
+		if err != nil {
+			if mode == types.Allocate {
+				rollback(ctx, ipam, ranges[:len(newips)], containerID, ifName)
+			}
+			return nil, err
+		}
+		newips = append(newips, newip)
+	}
+
+	return newips, nil
+}
+
+// ResolveRanges determines the set of ranges ipamConf's allocate/deallocate
+// calls and checks operate against: a node slice (if NodeSliceName is set),
+// otherwise ipamConf.Ranges or the legacy singular Range, with pool_name
+// resolved into ranges[0].Range where applicable. It returns a fresh slice
+// rather than ipamConf.Ranges itself, since callers (e.g. whereaboutsd, which
+// holds one IPAMConfig across many concurrent gRPC calls) must not have their
+// pool_name resolution mutate a config shared with other in-flight calls.
+func ResolveRanges(ctx context.Context, ipam Store, ipamConf types.IPAMConfig) ([]types.RangeConfiguration, error) {
+	if ipamConf.NodeSliceName != "" {
+		nodeSlicer, ok := ipam.(NodeSlicer)
+		if !ok {
+			return nil, fmt.Errorf("IPManagement: datastore %s does not support node_slice_name", ipamConf.Datastore)
+		}
+		r, err := nodeSlicer.GetNodeSlice(ctx, ipamConf.NodeSliceName, ipamConf.NodeName)
+		if err != nil {
+			return nil, err
+		}
+		return []types.RangeConfiguration{r}, nil
+	}
+
+	var ranges []types.RangeConfiguration
+	if len(ipamConf.Ranges) > 0 {
+		// Copy rather than alias ipamConf.Ranges: pool_name resolution below
+		// writes into ranges[0], and ipamConf may be shared across callers.
+		ranges = append([]types.RangeConfiguration{}, ipamConf.Ranges...)
+	} else {
+		// Fall back to the single-range configuration for backwards compatibility
+		ranges = []types.RangeConfiguration{{
+			Range:      ipamConf.Range,
+			RangeStart: ipamConf.RangeStart,
+			RangeEnd:   ipamConf.RangeEnd,
+			OmitRanges: ipamConf.OmitRanges,
+		}}
+	}
+
+	// poolNameResolved tracks whether pool_name was actually turned into a
+	// range, independent of whether ranges[0].Range is already non-empty
+	// from the unscoped range/ranges fallback above: force_pool_name must
+	// reject that fallback too, not just a genuinely empty Range.
+	poolNameResolved := false
+
+	// An explicit range/ranges config takes precedence over pool_name, so
+	// only attempt resolution when one wasn't already given -- unless
+	// force_pool_name insists pool_name itself must resolve.
+	if ipamConf.PoolName != "" && (ranges[0].Range == "" || ipamConf.ForcePoolName) {
+		// Resolve pool_name via the flat-file map first, falling back to
+		// asking the Store to resolve it through a Pool CR.
+		if resolved, ok := ipamConf.Pools[ipamConf.PoolName]; ok {
+			ranges[0].Range = resolved
+			poolNameResolved = true
+		} else if resolver, ok := ipam.(PoolNameResolver); ok {
+			resolved, err := resolver.ResolvePoolName(ctx, ipamConf.PoolName)
+			if err != nil {
+				return nil, err
+			}
+			ranges[0].Range = resolved
+			poolNameResolved = true
+		} else {
+			return nil, fmt.Errorf("IPManagement: datastore %s does not support pool_name", ipamConf.Datastore)
+		}
+	}
+
+	if ipamConf.ForcePoolName && !poolNameResolved {
+		return nil, fmt.Errorf("IPManagement: force_pool_name is set but pool %q could not be resolved", ipamConf.PoolName)
 	}
 
-	// handle the ip add/del until successful
+	return ranges, nil
+}
+
+// IsAllocated reports whether the (containerID, ifName) tuple currently holds a
+// reservation in ipRange. Pre-migration records with no IfName are matched on
+// containerID alone.
+func IsAllocated(ctx context.Context, ipam Store, ipRange string, containerID string, ifName string) (bool, error) {
+	pool, err := ipam.GetIPPool(ctx, ipRange)
+	if err != nil {
+		return false, err
+	}
+	for _, reservation := range pool.Allocations() {
+		if getMatchingIPReservation(reservation, containerID, ifName) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func getMatchingIPReservation(reservation types.IPReservation, containerID string, ifName string) bool {
+	if reservation.ContainerID != containerID {
+		return false
+	}
+	return reservation.IfName == "" || reservation.IfName == ifName
+}
+
+// ipManagementForRange handles the ip add/del for a single range until successful
+func ipManagementForRange(ctx context.Context, ipam Store, mode int, rangeConf types.RangeConfiguration, containerID string, ifName string, podRef string) (net.IPNet, error) {
+	var newip net.IPNet
+	var pool IPPool
+	var err error
+
 RETRYLOOP:
 	for j := 0; j < DatastoreRetries; j++ {
 		select {
 		case <-ctx.Done():
 			// return last available newip and err
-			logging.Errorf("context is done for ip pool %s, returning last ip %s: error %v", ipamConf.Range, newip.String(), err)
+			logging.Errorf("context is done for ip pool %s, returning last ip %s: error %v", rangeConf.Range, newip.String(), err)
 			return newip, err
 		default:
 			// retry the IPAM loop if the context has not been cancelled
 		}
 
-		pool, err = ipam.GetIPPool(ctx, ipamConf.Range)
+		pool, err = ipam.GetIPPool(ctx, rangeConf.Range)
 		if err != nil {
 			logging.Errorf("IPAM error reading pool allocations (attempt: %d): %v", j, err)
 			if e, ok := err.(temporary); ok && e.Temporary() {
@@ -102,13 +260,13 @@ RETRYLOOP:
 		var updatedreservelist []types.IPReservation
 		switch mode {
 		case types.Allocate:
-			newip, updatedreservelist, err = allocate.AssignIP(ipamConf, reservelist, containerID, podRef)
+			newip, updatedreservelist, err = allocate.AssignIP(rangeConf, reservelist, containerID, ifName, podRef)
 			if err != nil {
 				logging.Errorf("Error assigning IP: %v", err)
 				return newip, err
 			}
 		case types.Deallocate:
-			updatedreservelist, err = allocate.DeallocateIP(ipamConf.Range, reservelist, containerID)
+			updatedreservelist, err = allocate.DeallocateIP(rangeConf.Range, reservelist, containerID, ifName)
 			if err != nil {
 				logging.Errorf("Error deallocating IP: %v", err)
 				return newip, err
@@ -117,9 +275,9 @@ RETRYLOOP:
 
 		err = pool.Update(ctx, updatedreservelist)
 		if err != nil {
-			logging.Errorf("IPAM error updating pool %s (attempt: %d): %v", ipamConf.Range, j, err)
+			logging.Errorf("IPAM error updating pool %s (attempt: %d): %v", rangeConf.Range, j, err)
 			if e, ok := err.(temporary); ok && e.Temporary() {
-				logging.Errorf("IPAM error is temporary for pool %s: %v, retrying", ipamConf.Range, err)
+				logging.Errorf("IPAM error is temporary for pool %s: %v, retrying", rangeConf.Range, err)
 				continue
 			}
 			break RETRYLOOP
@@ -129,3 +287,13 @@ RETRYLOOP:
 
 	return newip, err
 }
+
+// rollback deallocates containerID's reservation from each of the given ranges,
+// used to undo a partially-successful multi-range allocation
+func rollback(ctx context.Context, ipam Store, ranges []types.RangeConfiguration, containerID string, ifName string) {
+	for _, r := range ranges {
+		if _, err := ipManagementForRange(ctx, ipam, types.Deallocate, r, containerID, ifName, ""); err != nil {
+			logging.Errorf("rollback: failed to deallocate IP from pool %s for container %s: %v", r.Range, containerID, err)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/dougbtv/whereabouts/pkg/types"
+)
+
+func TestStorage(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "storage")
+}
+
+func newTestBoltStore(path string) Store {
+	ipam, err := NewBoltIPAM(types.IPAMConfig{LocalDatastorePath: path})
+	Expect(err).NotTo(HaveOccurred())
+	return ipam
+}
+
+var _ = Describe("IPManagementWithStore pool_name/force_pool_name", func() {
+	var path string
+	var ipam Store
+
+	AfterEach(func() {
+		Expect(ipam.Close(context.Background())).To(Succeed())
+		os.Remove(path)
+	})
+
+	It("errors when force_pool_name is set but pool_name can't be resolved", func() {
+		path = "/tmp/whereabouts-storage-test.db"
+		ipam = newTestBoltStore(path)
+
+		ipamConf := types.IPAMConfig{
+			Datastore:      types.DatastoreLocal,
+			Range:          "10.10.0.0/24",
+			ForcePoolName:  true,
+			PoolName:       "does-not-exist",
+			RequestTimeout: 5,
+		}
+
+		_, err := IPManagementWithStore(types.Allocate, ipamConf, ipam, "container1", "eth0", "default/pod1")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("resolves pool_name via the flat-file Pools map", func() {
+		path = "/tmp/whereabouts-storage-test-2.db"
+		ipam = newTestBoltStore(path)
+
+		ipamConf := types.IPAMConfig{
+			Datastore:      types.DatastoreLocal,
+			ForcePoolName:  true,
+			PoolName:       "mypool",
+			Pools:          map[string]string{"mypool": "10.10.0.0/24"},
+			RequestTimeout: 5,
+		}
+
+		newips, err := IPManagementWithStore(types.Allocate, ipamConf, ipam, "container1", "eth0", "default/pod1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(newips).To(HaveLen(1))
+		Expect(newips[0].IP.String()).To(Equal("10.10.0.0"))
+	})
+
+	It("lets an explicit range win over an unresolvable pool_name when force_pool_name is unset", func() {
+		path = "/tmp/whereabouts-storage-test-3.db"
+		ipam = newTestBoltStore(path)
+
+		ipamConf := types.IPAMConfig{
+			Datastore:      types.DatastoreLocal,
+			Range:          "10.10.0.0/24",
+			PoolName:       "unresolvable-on-this-backend",
+			RequestTimeout: 5,
+		}
+
+		// The local backend doesn't implement PoolNameResolver and pool_name
+		// isn't in ipamConf.Pools, but an explicit Range is set and
+		// force_pool_name is false, so resolution should be skipped rather
+		// than erroring.
+		newips, err := IPManagementWithStore(types.Allocate, ipamConf, ipam, "container1", "eth0", "default/pod1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(newips).To(HaveLen(1))
+		Expect(newips[0].IP.String()).To(Equal("10.10.0.0"))
+	})
+
+	It("does not mutate the caller's Ranges slice when resolving pool_name", func() {
+		path = "/tmp/whereabouts-storage-test-4.db"
+		ipam = newTestBoltStore(path)
+
+		sharedRanges := []types.RangeConfiguration{{}}
+		ipamConf := types.IPAMConfig{
+			Datastore:      types.DatastoreLocal,
+			Ranges:         sharedRanges,
+			ForcePoolName:  true,
+			PoolName:       "mypool",
+			Pools:          map[string]string{"mypool": "10.10.0.0/24"},
+			RequestTimeout: 5,
+		}
+
+		_, err := IPManagementWithStore(types.Allocate, ipamConf, ipam, "container1", "eth0", "default/pod1")
+		Expect(err).NotTo(HaveOccurred())
+
+		// A daemon holds one IPAMConfig across many concurrent gRPC calls;
+		// resolving pool_name for this call must not leak into the shared
+		// Ranges backing array that a concurrent or later call also reads.
+		Expect(sharedRanges[0].Range).To(Equal(""))
+	})
+
+	It("errors immediately when node_slice_name is set against a backend with no NodeSlicer", func() {
+		path = "/tmp/whereabouts-storage-test-5.db"
+		ipam = newTestBoltStore(path)
+
+		ipamConf := types.IPAMConfig{
+			Datastore:      types.DatastoreLocal,
+			NodeSliceName:  "mypool",
+			RequestTimeout: 5,
+		}
+
+		// No Store backend implements NodeSlicer yet (see pkg/nodeslice's
+		// package doc), so this must fail loudly rather than silently
+		// falling back to some other range.
+		_, err := IPManagementWithStore(types.Allocate, ipamConf, ipam, "container1", "eth0", "default/pod1")
+		Expect(err).To(HaveOccurred())
+	})
+})
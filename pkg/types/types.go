@@ -0,0 +1,142 @@
+// Package types holds the configuration and reservation types shared
+// across whereabouts' CNI entrypoint and storage backends.
+package types
+
+import (
+	"net"
+
+	cnitypes "github.com/containernetworking/cni/pkg/types"
+)
+
+const (
+	// Allocate is the operation mode for IP assignment
+	Allocate = 0
+	// Deallocate is the operation mode for IP de-assignment
+	Deallocate = 1
+)
+
+const (
+	// DatastoreETCD is the etcd backend datastore identifier
+	DatastoreETCD = "etcd"
+	// DatastoreKubernetes is the kubernetes CRD backend datastore identifier
+	DatastoreKubernetes = "kubernetes"
+	// DatastoreLocal is the local boltdb backend datastore identifier
+	DatastoreLocal = "local"
+
+	// WhereaboutsConfigPath is the default path to the whereabouts config file
+	WhereaboutsConfigPath = "/etc/cni/net.d/whereabouts.d/whereabouts.conf"
+	// DefaultLocalDatastorePath is the default boltdb file path used by the local datastore
+	DefaultLocalDatastorePath = "/var/lib/cni/whereabouts/whereabouts.db"
+
+	// DefaultDaemonSocketPath is the default unix socket the whereaboutsd daemon listens on,
+	// and that the whereabouts-shim CNI plugin dials
+	DefaultDaemonSocketPath = "/var/run/whereabouts/daemon.sock"
+
+	// DefaultLeaseDuration is the default lease duration, in seconds
+	DefaultLeaseDuration = 10
+	// DefaultBackoff is the default backoff, in milliseconds
+	DefaultBackoff = 1000
+)
+
+// Net is the top level network configuration which whereabouts is passed by the CNI runtime
+type Net struct {
+	Name       string      `json:"name"`
+	CNIVersion string      `json:"cniVersion"`
+	IPAM       *IPAMConfig `json:"ipam"`
+}
+
+// RangeConfiguration describes a single IP range that whereabouts may allocate from.
+// A single CNI invocation may request IPs from more than one RangeConfiguration,
+// e.g. one per address family for dual-stack interfaces.
+type RangeConfiguration struct {
+	OmitRanges []string `json:"exclude,omitempty"`
+	Range      string   `json:"range"`
+	RangeStart net.IP   `json:"range_start,omitempty"`
+	RangeEnd   net.IP   `json:"range_end,omitempty"`
+}
+
+// IPAMConfig describes the expected json configuration for this plugin
+type IPAMConfig struct {
+	Name               string
+	Type               string               `json:"type"`
+	Routes             []*cnitypes.Route    `json:"routes"`
+	Datastore          string               `json:"datastore"`
+	Ranges             []RangeConfiguration `json:"ranges,omitempty"`
+	OmitRanges         []string             `json:"exclude,omitempty"`
+	DNS                cnitypes.DNS         `json:"dns"`
+	Range              string               `json:"range"`
+	RangeStart         net.IP               `json:"range_start,omitempty"`
+	RangeEnd           net.IP               `json:"range_end,omitempty"`
+	GatewayStr         string               `json:"gateway"`
+	Gateway            net.IP
+	LeaseDuration      int              `json:"lease_duration,omitempty"`
+	LogFile            string           `json:"log_file"`
+	LogLevel           string           `json:"log_level"`
+	Kubernetes         KubernetesConfig `json:"kubernetes,omitempty"`
+	LocalDatastorePath string           `json:"datastore_path,omitempty"`
+	ConfigurationPath  string           `json:"configuration_path"`
+	EtcdHost           string           `json:"etcd_host,omitempty"`
+	EtcdUsername       string           `json:"etcd_username,omitempty"`
+	EtcdPassword       string           `json:"etcd_password,omitempty"`
+	PodName            string
+	PodNamespace       string
+	NodeName           string
+	RequestTimeout     int    `json:"request_timeout,omitempty"`
+	LockRequestTimeout int    `json:"lock_request_timeout,omitempty"`
+	Backoff            int    `json:"backoff,omitempty"`
+	NodeSliceName      string `json:"node_slice_name,omitempty"`
+	PoolName           string `json:"pool_name,omitempty"`
+	ForcePoolName      bool   `json:"force_pool_name,omitempty"`
+	// Pools maps a pool_name to a concrete range for datastores that don't
+	// resolve pool names via a CR, populated from the global flat-file config
+	Pools map[string]string `json:"pools,omitempty"`
+}
+
+// KubernetesConfig describes the kubernetes-specific configuration options
+type KubernetesConfig struct {
+	KubeConfigPath string `json:"kubeconfig"`
+}
+
+// IPReservation describes an allocated IP, and the container/interface/pod it is
+// assigned to. IfName disambiguates multiple interfaces on the same pod that draw
+// from the same whereabouts pool; older records written before IfName existed have
+// it empty and are matched on ContainerID alone.
+type IPReservation struct {
+	IP          net.IP
+	ContainerID string
+	IfName      string
+	PodRef      string
+}
+
+// CIDRPoolSpec is the user-declared configuration of a CIDRPool: one large
+// parent range that whereabouts shards into a fixed-size block per node,
+// instead of every node contending for locks on a single pool.
+type CIDRPoolSpec struct {
+	CIDR             string   `json:"cidr"`
+	PerNodeBlockSize int      `json:"perNodeBlockSize"`
+	GatewayTemplate  string   `json:"gatewayTemplate,omitempty"`
+	Exclusions       []string `json:"exclusions,omitempty"`
+}
+
+// NodeBlockAllocation records the block of a CIDRPool that has been handed to a node
+type NodeBlockAllocation struct {
+	NodeName string `json:"nodeName"`
+	Block    string `json:"block"`
+}
+
+// CIDRPoolStatus records the block each node has been assigned so far
+type CIDRPoolStatus struct {
+	Allocations []NodeBlockAllocation `json:"allocations,omitempty"`
+}
+
+// CIDRPool models a cluster-wide pool that pkg/nodeslice.Reconcile carves into
+// Spec.PerNodeBlockSize blocks, one per node, recorded in Status. It is not
+// yet a registered CRD type: it has no TypeMeta/ObjectMeta, and nothing
+// watches CIDRPools or Nodes and calls Reconcile/writes Status back to a CR.
+// No Store backend implements NodeSlicer yet either, so IPAMConfig.NodeSliceName
+// can't actually resolve at runtime. See pkg/nodeslice's package doc.
+type CIDRPool struct {
+	Name   string
+	Spec   CIDRPoolSpec
+	Status CIDRPoolStatus
+}